@@ -0,0 +1,252 @@
+/* Copyright 2020 CLOUD&HEAT Technologies GmbH
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package openstack
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack"
+
+	"k8s.io/klog"
+)
+
+// NetworkingOpts holds everything the LBaaS controller needs to know about
+// how to provision L3 resources (ports, floating IPs, subnets) for load
+// balancers.
+type NetworkingOpts struct {
+	SubnetID            string   `yaml:"subnet_id"`
+	FloatingIPNetworkID string   `yaml:"floating_network_id"`
+	UseFloatingIPs      bool     `yaml:"use_floating_ips"`
+
+	// SubnetPoolID, if set, makes the controller allocate ports from any
+	// subnet belonging to this Neutron subnetpool that still has free IP
+	// capacity, instead of always using SubnetID. This lets a cluster grow
+	// past the size of a single subnet without operator intervention.
+	// SubnetID is still required as a fallback/default when the pool is
+	// exhausted or unset.
+	SubnetPoolID string `yaml:"subnet_pool_id,omitempty"`
+
+	// FloatingIPPool restricts floating IP re-use (see
+	// OpenStackL3PortManager.findFreeFloatingIP) to a fixed,
+	// pre-allocated set of addresses, e.g. because they are
+	// externally whitelisted. Leave empty to allow any unassociated
+	// floating IP in FloatingIPNetworkID to be re-used.
+	FloatingIPPool []string `yaml:"floating_ip_pool,omitempty"`
+
+	// DryRun makes the garbage collector only log what it would delete
+	// instead of actually deleting anything.
+	DryRun bool `yaml:"dry_run,omitempty"`
+}
+
+// OpenStackOpts configures how we authenticate against and talk to the
+// OpenStack APIs, as opposed to NetworkingOpts, which configures what we do
+// once connected.
+type OpenStackOpts struct {
+	AuthOpts gophercloud.AuthOptions `yaml:",inline"`
+
+	// EndpointType selects which of the endpoints published in the
+	// Keystone catalog is used (public, internal, admin). Defaults to
+	// "public" like gophercloud does.
+	EndpointType string `yaml:"endpoint_type,omitempty"`
+
+	// Insecure controls TLS certificate verification. It is a *bool,
+	// following the tri-state pattern used by Terraform's OpenStack/Swift
+	// backends: nil means "unset", and falls back to the OS_INSECURE
+	// environment variable (verify, if that is also unset or not a valid
+	// bool); an explicit value always wins over the environment, so
+	// Insecure=false means "verify, and I mean it" even if OS_INSECURE=true
+	// is set in the environment.
+	Insecure *bool `yaml:"insecure,omitempty"`
+
+	// CACertFile, ClientCertFile and ClientKeyFile let operators point
+	// the controller at an internal Keystone/Neutron with a private CA
+	// or mutual-TLS auth, instead of silently inheriting whatever TLS
+	// config gophercloud's http.DefaultClient happens to have.
+	CACertFile     string `yaml:"ca_cert_file,omitempty"`
+	ClientCertFile string `yaml:"client_cert_file,omitempty"`
+	ClientKeyFile  string `yaml:"client_key_file,omitempty"`
+
+	// RequestTimeout bounds how long a single request to an OpenStack
+	// API may take. Zero means no timeout, matching net/http defaults.
+	RequestTimeout time.Duration `yaml:"request_timeout,omitempty"`
+}
+
+type OpenStackClient struct {
+	provider *gophercloud.ProviderClient
+	opts     *OpenStackOpts
+}
+
+func endpointTypeOrDefault(endpointType string) gophercloud.Availability {
+	switch endpointType {
+	case "internalURL", "internal":
+		return gophercloud.AvailabilityInternal
+	case "adminURL", "admin":
+		return gophercloud.AvailabilityAdmin
+	case "", "publicURL", "public":
+		return gophercloud.AvailabilityPublic
+	default:
+		klog.Warningf("unknown endpoint_type %q, falling back to publicURL", endpointType)
+		return gophercloud.AvailabilityPublic
+	}
+}
+
+// insecureEnvDefault reports the value of OS_INSECURE, used as the fallback
+// for an unset Insecure. An empty or unparseable value defaults to false
+// (verify), the secure choice.
+func insecureEnvDefault() bool {
+	v, ok := os.LookupEnv("OS_INSECURE")
+	if !ok {
+		return false
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		klog.Warningf("OS_INSECURE=%q is not a valid bool, defaulting to false", v)
+		return false
+	}
+	return b
+}
+
+// buildTLSConfig turns the certificate-related OpenStackOpts fields into a
+// *tls.Config for the http.Client used by the ServiceClients we hand out.
+// A nil Insecure falls back to OS_INSECURE (verify, if that is also unset);
+// an explicit Insecure always overrides the environment.
+func buildTLSConfig(opts *OpenStackOpts) (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+
+	if opts.Insecure != nil {
+		tlsConfig.InsecureSkipVerify = *opts.Insecure
+	} else {
+		tlsConfig.InsecureSkipVerify = insecureEnvDefault()
+	}
+
+	if opts.CACertFile != "" {
+		caCert, err := ioutil.ReadFile(opts.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA cert file %q: %w", opts.CACertFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificates could be parsed from CA cert file %q", opts.CACertFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if opts.ClientCertFile != "" || opts.ClientKeyFile != "" {
+		if opts.ClientCertFile == "" || opts.ClientKeyFile == "" {
+			return nil, fmt.Errorf("client_cert_file and client_key_file must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(opts.ClientCertFile, opts.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate/key pair: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// retryingRoundTripper retries idempotent requests that fail with a
+// transient network error or a 5xx response, using a short bounded
+// exponential backoff. It does not retry requests with a body, since we
+// cannot safely know whether they were already applied server-side.
+type retryingRoundTripper struct {
+	next       http.RoundTripper
+	maxRetries int
+}
+
+func (rt *retryingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	wait := 250 * time.Millisecond
+	var resp *http.Response
+	var err error
+	for attempt := 0; attempt <= rt.maxRetries; attempt++ {
+		resp, err = rt.next.RoundTrip(req)
+		if err == nil && resp.StatusCode < 500 {
+			return resp, nil
+		}
+		if req.Body != nil {
+			// can't safely retry a request whose body we've already sent
+			return resp, err
+		}
+		if attempt == rt.maxRetries {
+			break
+		}
+		if resp != nil {
+			// drain and close so the underlying connection can be reused
+			// or released before we issue the next attempt; otherwise it
+			// leaks for as long as the transport keeps it open.
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		time.Sleep(wait)
+		wait *= 2
+	}
+	return resp, err
+}
+
+// NewOpenStackClient authenticates against Keystone using opts and returns
+// a client that can be used to build ServiceClients for individual
+// OpenStack services.
+func NewOpenStackClient(opts *OpenStackOpts) (*OpenStackClient, error) {
+	provider, err := openstack.NewClient(opts.AuthOpts.IdentityEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := buildTLSConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// clone http.DefaultTransport rather than starting from a bare
+	// http.Transport{}, so we keep its proxy-from-environment support and
+	// sane dial/idle-connection timeouts and only override TLS.
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	provider.HTTPClient = http.Client{
+		Timeout: opts.RequestTimeout,
+		Transport: &retryingRoundTripper{
+			next:       transport,
+			maxRetries: 3,
+		},
+	}
+
+	if err := openstack.Authenticate(provider, opts.AuthOpts); err != nil {
+		return nil, err
+	}
+
+	return &OpenStackClient{
+		provider: provider,
+		opts:     opts,
+	}, nil
+}
+
+// NewNetworkV2 returns a ServiceClient for the Neutron networking v2 API,
+// honouring the endpoint type and TLS/timeout settings configured on the
+// client.
+func (client *OpenStackClient) NewNetworkV2() (*gophercloud.ServiceClient, error) {
+	return openstack.NewNetworkV2(client.provider, gophercloud.EndpointOpts{
+		Availability: endpointTypeOrDefault(client.opts.EndpointType),
+	})
+}
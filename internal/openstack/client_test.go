@@ -0,0 +1,100 @@
+/* Copyright 2020 CLOUD&HEAT Technologies GmbH
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package openstack
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+const testCACert = `-----BEGIN CERTIFICATE-----
+MIIBeTCCAR+gAwIBAgIUJUJ5EjPGdIabg1tFFbROQ1u1ccswCgYIKoZIzj0EAwIw
+EjEQMA4GA1UEAwwHdGVzdC1jYTAeFw0yNjA3MjcxOTI1NDRaFw0zNjA3MjQxOTI1
+NDRaMBIxEDAOBgNVBAMMB3Rlc3QtY2EwWTATBgcqhkjOPQIBBggqhkjOPQMBBwNC
+AASaKZMmnQSn4nk8fSvT5AW09La7iVuatnwkMhrt9uFAYDQSnd20DxPh/dQwWNns
+dDvrHOvxn7p4u92Iyg+MaHtTo1MwUTAdBgNVHQ4EFgQUZUu4eiuVmAk5qmuW0Yi2
+agwkOGIwHwYDVR0jBBgwFoAUZUu4eiuVmAk5qmuW0Yi2agwkOGIwDwYDVR0TAQH/
+BAUwAwEB/zAKBggqhkjOPQQDAgNIADBFAiA9Yczwd2AbGSNsCHs2njWtblo6hqaI
+lOqZCKb2NmdqqAIhALpzjTvr/kqDD9oeKpqRkzvLud2CJljR+xUfAdXGQDQm
+-----END CERTIFICATE-----`
+
+func TestBuildTLSConfig_InsecureNilFallsBackToEnvDefault(t *testing.T) {
+	t.Setenv("OS_INSECURE", "true")
+
+	unsetConfig, err := buildTLSConfig(&OpenStackOpts{})
+	if err != nil {
+		t.Fatalf("buildTLSConfig(unset): %s", err)
+	}
+	if !unsetConfig.InsecureSkipVerify {
+		t.Errorf("Insecure=nil must fall back to OS_INSECURE=true, got InsecureSkipVerify=false")
+	}
+
+	explicitConfig, err := buildTLSConfig(&OpenStackOpts{Insecure: boolPtr(false)})
+	if err != nil {
+		t.Fatalf("buildTLSConfig(explicit): %s", err)
+	}
+	if explicitConfig.InsecureSkipVerify {
+		t.Errorf("an explicit Insecure=false must override OS_INSECURE=true, got InsecureSkipVerify=true")
+	}
+}
+
+func TestBuildTLSConfig_InsecureDefaultsToVerifyWithoutEnv(t *testing.T) {
+	unsetConfig, err := buildTLSConfig(&OpenStackOpts{})
+	if err != nil {
+		t.Fatalf("buildTLSConfig(unset): %s", err)
+	}
+	if unsetConfig.InsecureSkipVerify {
+		t.Errorf("Insecure=nil without OS_INSECURE set must not disable verification")
+	}
+
+	insecureConfig, err := buildTLSConfig(&OpenStackOpts{Insecure: boolPtr(true)})
+	if err != nil {
+		t.Fatalf("buildTLSConfig(insecure): %s", err)
+	}
+	if !insecureConfig.InsecureSkipVerify {
+		t.Errorf("Insecure=true must disable verification")
+	}
+}
+
+func TestBuildTLSConfig_HonoursCustomCABundle(t *testing.T) {
+	caFile, err := ioutil.TempFile("", "lbaas-ca-*.pem")
+	if err != nil {
+		t.Fatalf("failed to create temp CA file: %s", err)
+	}
+	defer os.Remove(caFile.Name())
+	if _, err := caFile.WriteString(testCACert); err != nil {
+		t.Fatalf("failed to write temp CA file: %s", err)
+	}
+	caFile.Close()
+
+	config, err := buildTLSConfig(&OpenStackOpts{CACertFile: caFile.Name()})
+	if err != nil {
+		t.Fatalf("buildTLSConfig: %s", err)
+	}
+	if config.RootCAs == nil {
+		t.Fatalf("expected RootCAs to be populated from CACertFile")
+	}
+	if len(config.RootCAs.Subjects()) != 1 {
+		t.Errorf("expected exactly one CA in the pool, got %d", len(config.RootCAs.Subjects()))
+	}
+}
+
+func TestBuildTLSConfig_RejectsCACertFileNotFound(t *testing.T) {
+	_, err := buildTLSConfig(&OpenStackOpts{CACertFile: "/does/not/exist.pem"})
+	if err == nil {
+		t.Fatalf("expected an error for a missing CA cert file")
+	}
+}
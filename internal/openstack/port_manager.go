@@ -15,7 +15,10 @@
 package openstack
 
 import (
+	"encoding/binary"
 	"errors"
+	"net"
+	"sync"
 	"time"
 
 	"github.com/gophercloud/gophercloud"
@@ -24,6 +27,7 @@ import (
 	portsv2 "github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
 	subnetsv2 "github.com/gophercloud/gophercloud/openstack/networking/v2/subnets"
 	"github.com/gophercloud/gophercloud/pagination"
+	"github.com/prometheus/client_golang/prometheus"
 
 	"k8s.io/klog"
 )
@@ -37,8 +41,118 @@ var (
 	ErrFloatingIPMissing = errors.New("Expected floating IP was not found")
 	ErrFixedIPMissing    = errors.New("Port has no IP address assigned")
 	ErrPortIsNil = errors.New("The port is nil")
+	ErrNoSubnetCapacity  = errors.New("No subnet in the configured subnet pool has free IP capacity")
+	ErrFloatingIPPoolExhausted = errors.New("No free floating IP left in the configured FloatingIPPool")
 )
 
+const (
+	// maxGCRetries bounds the number of retries (on top of the first attempt)
+	// the garbage collector makes to delete a single port or floating IP
+	// before giving up and leaving it
+	// for the next reconciler pass. Kept low because the http.RoundTripper
+	// installed by NewOpenStackClient already retries body-less requests
+	// (which includes every Delete below) a few times on 5xx; this loop
+	// only needs to additionally cover 409s that the transport doesn't.
+	maxGCRetries  = 2
+	gcInitialWait = 1 * time.Second
+)
+
+var (
+	portsDeletedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "lbaas_openstack_ports_deleted_total",
+		Help: "Number of managed ports successfully deleted by the garbage collector.",
+	})
+	portDeleteRetriesTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "lbaas_openstack_port_delete_retries_total",
+		Help: "Number of retries issued while deleting managed ports.",
+	})
+	portsLeakedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "lbaas_openstack_ports_leaked_total",
+		Help: "Number of managed ports that could not be deleted after exhausting retries.",
+	})
+	fipsDeletedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "lbaas_openstack_floatingips_deleted_total",
+		Help: "Number of unused floating IPs successfully deleted by the garbage collector.",
+	})
+	fipDeleteRetriesTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "lbaas_openstack_floatingip_delete_retries_total",
+		Help: "Number of retries issued while deleting unused floating IPs.",
+	})
+	fipsLeakedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "lbaas_openstack_floatingips_leaked_total",
+		Help: "Number of floating IPs that could not be deleted after exhausting retries.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(
+		portsDeletedTotal,
+		portDeleteRetriesTotal,
+		portsLeakedTotal,
+		fipsDeletedTotal,
+		fipDeleteRetriesTotal,
+		fipsLeakedTotal,
+	)
+}
+
+// isRetryableDeleteError reports whether err is the kind of transient error
+// worth retrying a delete for: 409 (conflict, e.g. the resource is still
+// attached to something) or a 5xx. Anything else - in particular 400/401/403
+// - is not transient and retrying it would just waste time before an
+// inevitable failure, so those are returned to the caller immediately.
+func isRetryableDeleteError(err error) bool {
+	if errors.As(err, &gophercloud.ErrDefault409{}) {
+		return true
+	}
+	if errors.As(err, &gophercloud.ErrDefault500{}) {
+		return true
+	}
+	if errors.As(err, &gophercloud.ErrDefault502{}) {
+		return true
+	}
+	if errors.As(err, &gophercloud.ErrDefault503{}) {
+		return true
+	}
+	if errors.As(err, &gophercloud.ErrDefault504{}) {
+		return true
+	}
+	var unexpected gophercloud.ErrUnexpectedResponseCode
+	if errors.As(err, &unexpected) {
+		return unexpected.Actual >= 500
+	}
+	return false
+}
+
+// deleteWithBackoff retries op with exponential backoff, treating a 404 (the
+// resource is already gone) as success and only retrying errors that
+// isRetryableDeleteError considers transient. It gives up after
+// maxGCRetries retries so a single stubborn resource cannot stall the
+// garbage collector forever; the caller is expected to leave the resource
+// for the next reconciler pass in that case.
+func deleteWithBackoff(op func() error, retries prometheus.Counter) error {
+	wait := gcInitialWait
+	var err error
+	for attempt := 0; attempt <= maxGCRetries; attempt++ {
+		err = op()
+		if err == nil {
+			return nil
+		}
+		if errors.As(err, &gophercloud.ErrDefault404{}) {
+			return nil
+		}
+		if !isRetryableDeleteError(err) {
+			return err
+		}
+		if attempt == maxGCRetries {
+			break
+		}
+		retries.Inc()
+		time.Sleep(wait)
+		wait *= 2
+	}
+	return err
+}
+
 // We need options which are not included in the default gophercloud struct
 type CustomCreateOpts struct {
 	NetworkID           string                `json:"network_id" required:"true"`
@@ -67,14 +181,42 @@ type L3PortManager interface {
 	CleanUnusedPorts(usedPorts []string) error
 	GetAvailablePorts() ([]string, error)
 	GetExternalAddress(portID string) (string, string, error)
-	GetInternalAddress(portID string) (string, error)
+	GetExternalAddresses(portID string) ([]string, error)
+	GetInternalAddress(portID string) (string, string, error)
+	AssociateFloatingIP(portID, fipID string) error
+	DisassociateFloatingIP(fipID string) error
+	ListFloatingIPsForPort(portID string) ([]string, error)
+}
+
+// subnetPoolCacheTTL matches the TTL the port cache itself uses, so that
+// subnet capacity accounting is refreshed on the same cadence as the data
+// (used ports per subnet) it is derived from.
+const subnetPoolCacheTTL = 30 * time.Second
+
+// poolSubnet tracks our best-effort accounting of a subnetpool member
+// subnet's free IP capacity.
+type poolSubnet struct {
+	id        string
+	capacity  int
+	allocated int
+}
+
+// subnetPoolAccounting is a small in-memory cache of which subnets in
+// NetworkingOpts.SubnetPoolID still have free IP capacity, refreshed at
+// most every subnetPoolCacheTTL so that picking a subnet for a new port
+// doesn't require a live Neutron round-trip on every ProvisionPort call.
+type subnetPoolAccounting struct {
+	mu        sync.Mutex
+	expiresAt time.Time
+	subnets   []poolSubnet
 }
 
 type OpenStackL3PortManager struct {
-	client    *gophercloud.ServiceClient
-	networkID string
-	cfg       *NetworkingOpts
-	cache     PortCache
+	client     *gophercloud.ServiceClient
+	networkID  string
+	cfg        *NetworkingOpts
+	cache      PortCache
+	subnetPool *subnetPoolAccounting
 }
 
 func (client *OpenStackClient) NewOpenStackL3PortManager(networkConfig *NetworkingOpts) (*OpenStackL3PortManager, error) {
@@ -90,20 +232,197 @@ func (client *OpenStackClient) NewOpenStackL3PortManager(networkConfig *Networki
 
 	networkID := subnet.NetworkID
 
-	return &OpenStackL3PortManager{
+	pm := &OpenStackL3PortManager{
 		client:    networkingclient,
 		cfg:       networkConfig,
 		networkID: networkID,
 		cache: NewPortCache(
 			networkingclient,
-			30*time.Second,
+			subnetPoolCacheTTL,
 			TagLBManagedPort,
 			networkConfig.UseFloatingIPs,
 		),
-	}, nil
+	}
+	if networkConfig.SubnetPoolID != "" {
+		pm.subnetPool = &subnetPoolAccounting{}
+	}
+	return pm, nil
+}
+
+// ipRangeSize estimates how many addresses an allocation pool spans. It only
+// understands IPv4 ranges; anything else (notably IPv6, whose pools are far
+// too large to enumerate this way) is reported as unboundedCapacity so that
+// such subnets are never treated as exhausted by our own accounting.
+const unboundedCapacity = 1 << 30
+
+func ipRangeSize(start, end string) int {
+	startIP := net.ParseIP(start).To4()
+	endIP := net.ParseIP(end).To4()
+	if startIP == nil || endIP == nil {
+		return unboundedCapacity
+	}
+	size := int(binary.BigEndian.Uint32(endIP)) - int(binary.BigEndian.Uint32(startIP)) + 1
+	if size <= 0 {
+		return unboundedCapacity
+	}
+	return size
+}
+
+// refreshSubnetPool (re-)lists the subnets belonging to cfg.SubnetPoolID and
+// tallies, for each, how many of our own managed ports already sit in it, so
+// ProvisionPort can pick one that still has room.
+func (pm *OpenStackL3PortManager) refreshSubnetPool() error {
+	pager := subnetsv2.List(pm.client, subnetsv2.ListOpts{
+		SubnetPoolID: pm.cfg.SubnetPoolID,
+	})
+
+	subnets := make([]poolSubnet, 0)
+	err := pager.EachPage(func(page pagination.Page) (bool, error) {
+		pageSubnets, err := subnetsv2.ExtractSubnets(page)
+		if err != nil {
+			return false, err
+		}
+		for _, subnet := range pageSubnets {
+			capacity := 0
+			for _, pool := range subnet.AllocationPools {
+				capacity += ipRangeSize(pool.Start, pool.End)
+			}
+			subnets = append(subnets, poolSubnet{id: subnet.ID, capacity: capacity})
+		}
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	ports, err := pm.cache.GetPorts()
+	if err != nil {
+		return err
+	}
+	allocated := make(map[string]int)
+	for _, port := range ports {
+		for _, fixedIP := range port.FixedIPs {
+			allocated[fixedIP.SubnetID]++
+		}
+	}
+	for i := range subnets {
+		subnets[i].allocated = allocated[subnets[i].id]
+	}
+
+	pm.subnetPool.mu.Lock()
+	pm.subnetPool.subnets = subnets
+	pm.subnetPool.expiresAt = time.Now().Add(subnetPoolCacheTTL)
+	pm.subnetPool.mu.Unlock()
+	return nil
+}
+
+// candidateSubnets returns the subnetpool's member subnets that still have
+// free IP capacity, ordered as returned by Neutron, refreshing the cached
+// accounting first if it has gone stale.
+func (pm *OpenStackL3PortManager) candidateSubnets() ([]string, error) {
+	pm.subnetPool.mu.Lock()
+	stale := time.Now().After(pm.subnetPool.expiresAt)
+	pm.subnetPool.mu.Unlock()
+	if stale {
+		if err := pm.refreshSubnetPool(); err != nil {
+			return nil, err
+		}
+	}
+
+	pm.subnetPool.mu.Lock()
+	defer pm.subnetPool.mu.Unlock()
+
+	candidates := make([]string, 0, len(pm.subnetPool.subnets))
+	for _, subnet := range pm.subnetPool.subnets {
+		if subnet.allocated < subnet.capacity {
+			candidates = append(candidates, subnet.id)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, ErrNoSubnetCapacity
+	}
+	return candidates, nil
+}
+
+// findFreeFloatingIP looks for an already-allocated floating IP in the
+// configured network (optionally restricted to the pre-allocated FIP pool)
+// which is not yet bound to a port. It is used to avoid churning through
+// the tenant's FIP quota by re-using addresses that were freed up again,
+// e.g. by a previous DisassociateFloatingIP call or manual cleanup.
+func (pm *OpenStackL3PortManager) findFreeFloatingIP() (*floatingipsv2.FloatingIP, error) {
+	var free *floatingipsv2.FloatingIP
+
+	pager := floatingipsv2.List(pm.client, floatingipsv2.ListOpts{
+		FloatingNetworkID: pm.cfg.FloatingIPNetworkID,
+		PortID:            "",
+	})
+
+	err := pager.EachPage(func(page pagination.Page) (bool, error) {
+		fips, err := floatingipsv2.ExtractFloatingIPs(page)
+		if err != nil {
+			return false, err
+		}
+		for i := range fips {
+			fip := fips[i]
+			if fip.PortID != "" {
+				// gophercloud's BuildQueryString skips fields equal to their
+				// zero value, so PortID: "" above is never actually sent as
+				// a query filter and List returns every floating IP in the
+				// network. Re-check client-side, like deleteUnusedFloatingIPs
+				// does, or we risk stealing an address from a live port.
+				continue
+			}
+			if len(pm.cfg.FloatingIPPool) > 0 && !stringInSlice(fip.FloatingIP, pm.cfg.FloatingIPPool) {
+				// operator restricted us to a fixed, externally-whitelisted
+				// pool of addresses; ignore everything else
+				continue
+			}
+			free = &fip
+			return false, nil
+		}
+		return true, nil
+	})
+
+	return free, err
+}
+
+func stringInSlice(needle string, haystack []string) bool {
+	for _, v := range haystack {
+		if v == needle {
+			return true
+		}
+	}
+	return false
 }
 
 func (pm *OpenStackL3PortManager) provisionFloatingIP(portID string) error {
+	if free, err := pm.findFreeFloatingIP(); err != nil {
+		return err
+	} else if free != nil {
+		_, err := floatingipsv2.Update(pm.client, free.ID, floatingipsv2.UpdateOpts{
+			PortID: &portID,
+		}).Extract()
+		if err != nil {
+			return err
+		}
+
+		// the floating IP may already carry the management tag from a
+		// previous allocation; (re-)apply it defensively in case it was
+		// picked up from the pre-allocated pool instead.
+		_, err = tags.ReplaceAll(pm.client, "floatingips", free.ID, tags.ReplaceAllOpts{
+			Tags: []string{TagLBManagedPort},
+		}).Extract()
+		return err
+	}
+
+	if len(pm.cfg.FloatingIPPool) > 0 {
+		// operators who configure a fixed pool do so because addresses
+		// outside of it are not usable (e.g. not whitelisted through an
+		// external firewall); falling back to an unrestricted Create would
+		// silently hand out an address they can't actually reach.
+		return ErrFloatingIPPoolExhausted
+	}
+
 	fip, err := floatingipsv2.Create(
 		pm.client,
 		floatingipsv2.CreateOpts{
@@ -143,18 +462,52 @@ func boolPtr(v bool) *bool {
 	return &v
 }
 
+// candidateProvisioningSubnets returns the subnet IDs ProvisionPort should
+// try, in order. With SubnetPoolID configured, that is every subnet in the
+// pool that our own accounting believes still has room; SubnetID is always
+// appended last as a final fallback.
+func (pm *OpenStackL3PortManager) candidateProvisioningSubnets() ([]string, error) {
+	if pm.cfg.SubnetPoolID == "" {
+		return []string{pm.cfg.SubnetID}, nil
+	}
+
+	candidates, err := pm.candidateSubnets()
+	if err != nil && err != ErrNoSubnetCapacity {
+		return nil, err
+	}
+	return append(candidates, pm.cfg.SubnetID), nil
+}
+
 func (pm *OpenStackL3PortManager) ProvisionPort() (string, error) {
-	port, err := portsv2.Create(
-		pm.client,
-		CustomCreateOpts{
-			NetworkID:   pm.networkID,
-			Description: DescriptionLBManagedPort,
-			FixedIPs: []portsv2.IP{
-				{SubnetID: pm.cfg.SubnetID},
+	subnetIDs, err := pm.candidateProvisioningSubnets()
+	if err != nil {
+		return "", err
+	}
+
+	var port *portsv2.Port
+	var usedSubnetID string
+	for i, subnetID := range subnetIDs {
+		port, err = portsv2.Create(
+			pm.client,
+			CustomCreateOpts{
+				NetworkID:   pm.networkID,
+				Description: DescriptionLBManagedPort,
+				FixedIPs: []portsv2.IP{
+					{SubnetID: subnetID},
+				},
+				PortSecurityEnabled: boolPtr(false),
 			},
-			PortSecurityEnabled: boolPtr(false),
-		},
-	).Extract()
+		).Extract()
+		if err == nil {
+			usedSubnetID = subnetID
+			break
+		}
+		if _, outOfIPs := err.(gophercloud.ErrDefault409); outOfIPs && i < len(subnetIDs)-1 {
+			klog.Warningf("subnet %q has no free IPs left, trying the next candidate subnet", subnetID)
+			continue
+		}
+		return "", err
+	}
 	// XXX: this is meh because we can only set the tag after the port was
 	// created. If we get killed between the previous line and setting the
 	// tag, the port will linger, unusedly.
@@ -192,6 +545,17 @@ func (pm *OpenStackL3PortManager) ProvisionPort() (string, error) {
 	}
 
 	pm.cache.Invalidate()
+	if pm.subnetPool != nil {
+		// account for the new port locally instead of expiring the cached
+		// accounting, so the next ProvisionPort call needs no refresh
+		pm.subnetPool.mu.Lock()
+		for i := range pm.subnetPool.subnets {
+			if pm.subnetPool.subnets[i].id == usedSubnetID {
+				pm.subnetPool.subnets[i].allocated++
+			}
+		}
+		pm.subnetPool.mu.Unlock()
+	}
 	return port.ID, nil
 }
 
@@ -206,7 +570,6 @@ func (pm *OpenStackL3PortManager) deleteUnusedFloatingIPs() error {
 	toDelete := make([]string, 0)
 	err := pager.EachPage(func(page pagination.Page) (bool, error) {
 		fips, err := floatingipsv2.ExtractFloatingIPs(page)
-		klog.Warningf("Looking at floating ip %q (err: %s)", fips, err);
 		if err != nil {
 			return false, err
 		}
@@ -219,19 +582,29 @@ func (pm *OpenStackL3PortManager) deleteUnusedFloatingIPs() error {
 		return true, nil
 	})
 
+	if pm.cfg.DryRun {
+		for _, fipID := range toDelete {
+			klog.Warningf("[DryRun] Would delete orphaned floating ip %q", fipID)
+		}
+		return err
+	}
+
 	// even in case of an error, we can at least try to delete the fips we
 	// already gathered
 	for _, fipID := range toDelete {
-		klog.Warningf("Deleting floating ip %q", fipID)
-		/*
-		deleteErr := floatingipsv2.Delete(pm.client, fipID).ExtractErr()
+		id := fipID
+		deleteErr := deleteWithBackoff(func() error {
+			return floatingipsv2.Delete(pm.client, id).ExtractErr()
+		}, fipDeleteRetriesTotal)
 		if deleteErr != nil {
 			klog.Warningf(
-				"Failed to delete orphaned floating ip %q: %s. The operation will be retried later.",
+				"resource leak: failed to delete orphaned floating ip %q after retries: %s. It will be picked up by the next reconciler pass.",
 				fipID,
 				deleteErr.Error())
+			fipsLeakedTotal.Inc()
+			continue
 		}
-		*/
+		fipsDeletedTotal.Inc()
 	}
 
 	return err
@@ -239,7 +612,6 @@ func (pm *OpenStackL3PortManager) deleteUnusedFloatingIPs() error {
 
 func (pm *OpenStackL3PortManager) CleanUnusedPorts(usedPorts []string) error {
 	ports, err := pm.cache.GetPorts()
-	klog.Warningf("Used ports: %s", usedPorts);
 	if err != nil {
 		return err
 	}
@@ -249,28 +621,123 @@ func (pm *OpenStackL3PortManager) CleanUnusedPorts(usedPorts []string) error {
 	}
 
 	anyDeleted := false
+	anyUnused := false
 	for _, port := range ports {
 		if _, inUse := usedPortsMap[port.ID]; inUse {
 			continue
 		}
-		klog.Warningf("[Dummy] Deleting port %s", port.ID);
-		// port not in use, issue deletion
-		/*
-		err := portsv2.Delete(pm.client, port.ID).ExtractErr()
-		if err != nil {
-			klog.Warningf("Failed to delete unused port %q: %s. The operation will be retried later.", port.ID, err)
+		anyUnused = true
+
+		if pm.cfg.DryRun {
+			klog.Warningf("[DryRun] Would delete unused port %s", port.ID)
+			continue
+		}
+
+		id := port.ID
+		deleteErr := deleteWithBackoff(func() error {
+			return portsv2.Delete(pm.client, id).ExtractErr()
+		}, portDeleteRetriesTotal)
+		if deleteErr != nil {
+			klog.Warningf("resource leak: failed to delete unused port %q after retries: %s. It will be picked up by the next reconciler pass.", port.ID, deleteErr)
+			portsLeakedTotal.Inc()
+			continue
 		}
-		*/
+		portsDeletedTotal.Inc()
 		anyDeleted = true
 	}
 
 	if anyDeleted {
 		pm.cache.Invalidate()
+	}
+	// deleteUnusedFloatingIPs is itself DryRun-aware, so it must run
+	// whenever we found unused ports, not only when we actually deleted
+	// one, or enabling DryRun silently hides the FIP sweep entirely.
+	if anyUnused {
 		return pm.deleteUnusedFloatingIPs()
 	}
 	return nil
 }
 
+// sweepOrphansByDescription catches ports that crashed between creation and
+// tagging (see the comment in ProvisionPort) and therefore never picked up
+// TagLBManagedPort. It falls back to matching on DescriptionLBManagedPort,
+// which is set before the tag, so it is a reliable marker even for untagged
+// leftovers. Only ports are swept; untagged floating IPs are not.
+func (pm *OpenStackL3PortManager) sweepOrphansByDescription(usedPorts []string) error {
+	usedPortsMap := make(map[string]bool)
+	for _, portID := range usedPorts {
+		usedPortsMap[portID] = true
+	}
+
+	var errs []error
+	pager := portsv2.List(pm.client, portsv2.ListOpts{})
+	err := pager.EachPage(func(page pagination.Page) (bool, error) {
+		ports, err := portsv2.ExtractPorts(page)
+		if err != nil {
+			return false, err
+		}
+		for _, port := range ports {
+			if port.Description != DescriptionLBManagedPort {
+				continue
+			}
+			if _, inUse := usedPortsMap[port.ID]; inUse {
+				continue
+			}
+			if pm.cfg.DryRun {
+				klog.Warningf("[DryRun] Would delete orphaned untagged port %s", port.ID)
+				continue
+			}
+			id := port.ID
+			if deleteErr := deleteWithBackoff(func() error {
+				return portsv2.Delete(pm.client, id).ExtractErr()
+			}, portDeleteRetriesTotal); deleteErr != nil {
+				portsLeakedTotal.Inc()
+				errs = append(errs, deleteErr)
+				continue
+			}
+			portsDeletedTotal.Inc()
+		}
+		return true, nil
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// StartReconciler runs CleanUnusedPorts (and the untagged-orphan fallback
+// sweep) on a fixed interval, independently of ProvisionPort invalidating
+// the cache. This ensures that resources orphaned by a crash between
+// create and tag are eventually swept, even if no further ProvisionPort
+// calls happen to trigger a cache refresh. It blocks until stopCh is
+// closed, so callers should run it in its own goroutine.
+func (pm *OpenStackL3PortManager) StartReconciler(usedPorts func() ([]string, error), interval time.Duration, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			used, err := usedPorts()
+			if err != nil {
+				klog.Warningf("reconciler: failed to determine used ports, skipping this pass: %s", err)
+				continue
+			}
+			if err := pm.CleanUnusedPorts(used); err != nil {
+				klog.Warningf("reconciler: CleanUnusedPorts failed: %s", err)
+			}
+			if err := pm.sweepOrphansByDescription(used); err != nil {
+				klog.Warningf("reconciler: orphan sweep failed: %s", err)
+			}
+		}
+	}
+}
+
 func (pm *OpenStackL3PortManager) GetAvailablePorts() ([]string, error) {
 	ports, err := pm.cache.GetPorts()
 	if err != nil {
@@ -309,18 +776,116 @@ func (pm *OpenStackL3PortManager) GetExternalAddress(portID string) (string, str
 	return port.FixedIPs[0].IPAddress, "", nil
 }
 
-func (pm *OpenStackL3PortManager) GetInternalAddress(portID string) (string, error) {
+// GetExternalAddresses returns every floating IP currently associated with
+// portID. Unlike GetExternalAddress, which only ever reports a single
+// address, this allows a port to be advertised on more than one public
+// address at once, e.g. for a dual-stack IPv4/IPv6 setup or while migrating
+// between two floating IPs. With UseFloatingIPs off, it returns the port's
+// first fixed IP instead, like GetExternalAddress.
+func (pm *OpenStackL3PortManager) GetExternalAddresses(portID string) ([]string, error) {
 	port, _, err := pm.cache.GetPortByID(portID)
-	
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if port == nil {
-		return "", ErrPortIsNil
+		return nil, ErrPortIsNil
+	}
+
+	if !pm.cfg.UseFloatingIPs {
+		if len(port.FixedIPs) == 0 {
+			return nil, ErrFixedIPMissing
+		}
+		return []string{port.FixedIPs[0].IPAddress}, nil
+	}
+
+	addresses, err := pm.ListFloatingIPsForPort(portID)
+	if err != nil {
+		return nil, err
+	}
+	if len(addresses) == 0 {
+		return nil, ErrFloatingIPMissing
+	}
+	return addresses, nil
+}
+
+// ListFloatingIPsForPort returns the floating IP addresses (not IDs)
+// currently associated with portID.
+func (pm *OpenStackL3PortManager) ListFloatingIPsForPort(portID string) ([]string, error) {
+	addresses := make([]string, 0)
+	pager := floatingipsv2.List(pm.client, floatingipsv2.ListOpts{
+		PortID: portID,
+	})
+	err := pager.EachPage(func(page pagination.Page) (bool, error) {
+		fips, err := floatingipsv2.ExtractFloatingIPs(page)
+		if err != nil {
+			return false, err
+		}
+		for _, fip := range fips {
+			addresses = append(addresses, fip.FloatingIP)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return addresses, nil
+}
+
+// AssociateFloatingIP binds an existing floating IP to portID, in addition
+// to whatever floating IPs are already associated with it.
+func (pm *OpenStackL3PortManager) AssociateFloatingIP(portID, fipID string) error {
+	_, err := floatingipsv2.Update(pm.client, fipID, floatingipsv2.UpdateOpts{
+		PortID: &portID,
+	}).Extract()
+	if err != nil {
+		return err
+	}
+
+	// tag it like provisionFloatingIP does for its reuse path, so that
+	// externally-attached FIPs are visible to deleteUnusedFloatingIPs once
+	// they get disassociated again instead of leaking forever.
+	_, err = tags.ReplaceAll(pm.client, "floatingips", fipID, tags.ReplaceAllOpts{
+		Tags: []string{TagLBManagedPort},
+	}).Extract()
+	if err != nil {
+		return err
+	}
+
+	pm.cache.Invalidate()
+	return nil
+}
+
+// DisassociateFloatingIP releases fipID from whatever port it is currently
+// bound to, without deleting it, so it can be re-used (see
+// findFreeFloatingIP) or associated with a different port. If it carries
+// TagLBManagedPort, deleteUnusedFloatingIPs deletes it on its next pass.
+func (pm *OpenStackL3PortManager) DisassociateFloatingIP(fipID string) error {
+	_, err := floatingipsv2.Update(pm.client, fipID, floatingipsv2.UpdateOpts{
+		PortID: new(string),
+	}).Extract()
+	if err != nil {
+		return err
+	}
+
+	pm.cache.Invalidate()
+	return nil
+}
+
+// GetInternalAddress returns portID's internal IP address together with the
+// ID of the subnet it was allocated from, so callers can tell which subnet
+// of a SubnetPoolID a given load balancer ended up on.
+func (pm *OpenStackL3PortManager) GetInternalAddress(portID string) (string, string, error) {
+	port, _, err := pm.cache.GetPortByID(portID)
+
+	if err != nil {
+		return "", "", err
+	}
+	if port == nil {
+		return "", "", ErrPortIsNil
 	}
 	if len(port.FixedIPs) == 0 {
-		return "", ErrFixedIPMissing
+		return "", "", ErrFixedIPMissing
 	}
 
-	return port.FixedIPs[0].IPAddress, nil
+	return port.FixedIPs[0].IPAddress, port.FixedIPs[0].SubnetID, nil
 }
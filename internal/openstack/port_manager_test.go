@@ -0,0 +1,102 @@
+/* Copyright 2020 CLOUD&HEAT Technologies GmbH
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package openstack
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIpRangeSize(t *testing.T) {
+	cases := []struct {
+		name  string
+		start string
+		end   string
+		want  int
+	}{
+		{"single address", "10.0.0.5", "10.0.0.5", 1},
+		{"small range", "10.0.0.10", "10.0.0.20", 11},
+		{"reversed start/end", "10.0.0.20", "10.0.0.10", unboundedCapacity},
+		{"ipv6 input", "2001:db8::1", "2001:db8::ffff", unboundedCapacity},
+		{"invalid input", "not-an-ip", "10.0.0.10", unboundedCapacity},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := ipRangeSize(c.start, c.end)
+			if got != c.want {
+				t.Errorf("ipRangeSize(%q, %q) = %d, want %d", c.start, c.end, got, c.want)
+			}
+		})
+	}
+}
+
+func TestCandidateProvisioningSubnets_NoPoolConfigured(t *testing.T) {
+	pm := &OpenStackL3PortManager{
+		cfg: &NetworkingOpts{SubnetID: "subnet-default"},
+	}
+
+	got, err := pm.candidateProvisioningSubnets()
+	if err != nil {
+		t.Fatalf("candidateProvisioningSubnets: %s", err)
+	}
+	if len(got) != 1 || got[0] != "subnet-default" {
+		t.Errorf("expected [subnet-default], got %v", got)
+	}
+}
+
+func TestCandidateProvisioningSubnets_FallsBackWhenPoolExhausted(t *testing.T) {
+	pm := &OpenStackL3PortManager{
+		cfg: &NetworkingOpts{SubnetID: "subnet-default", SubnetPoolID: "pool-1"},
+		subnetPool: &subnetPoolAccounting{
+			expiresAt: time.Now().Add(time.Hour),
+			subnets: []poolSubnet{
+				{id: "subnet-pool-a", capacity: 10, allocated: 10},
+				{id: "subnet-pool-b", capacity: 5, allocated: 5},
+			},
+		},
+	}
+
+	got, err := pm.candidateProvisioningSubnets()
+	if err != nil {
+		t.Fatalf("candidateProvisioningSubnets: %s", err)
+	}
+	if len(got) != 1 || got[0] != "subnet-default" {
+		t.Errorf("expected the exhausted pool to fall back to [subnet-default], got %v", got)
+	}
+}
+
+func TestCandidateProvisioningSubnets_PrefersPoolSubnetsWithCapacity(t *testing.T) {
+	pm := &OpenStackL3PortManager{
+		cfg: &NetworkingOpts{SubnetID: "subnet-default", SubnetPoolID: "pool-1"},
+		subnetPool: &subnetPoolAccounting{
+			expiresAt: time.Now().Add(time.Hour),
+			subnets: []poolSubnet{
+				{id: "subnet-pool-full", capacity: 10, allocated: 10},
+				{id: "subnet-pool-free", capacity: 10, allocated: 3},
+			},
+		},
+	}
+
+	got, err := pm.candidateProvisioningSubnets()
+	if err != nil {
+		t.Fatalf("candidateProvisioningSubnets: %s", err)
+	}
+	// the subnet with free capacity must be tried before the SubnetID fallback,
+	// and the exhausted one must not appear at all.
+	if len(got) != 2 || got[0] != "subnet-pool-free" || got[1] != "subnet-default" {
+		t.Errorf("expected [subnet-pool-free subnet-default], got %v", got)
+	}
+}